@@ -0,0 +1,281 @@
+// Package aprsis implements a streaming client for the APRS-IS network. It
+// dials a Tier-2 server, performs the login handshake, and delivers parsed
+// packets on a channel. Filters use the standard APRS-IS filter grammar and
+// can be changed on a live connection without reconnecting.
+package aprsis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/literadix/hamaprs"
+)
+
+// DefaultAddr is the address of the public rotating Tier-2 pool.
+const DefaultAddr = "rotate.aprs2.net:14580"
+
+// softwareName and softwareVersion identify this client in the login line,
+// as required by the APRS-IS server software.
+const (
+	softwareName    = "hamaprs"
+	softwareVersion = "1.0"
+)
+
+// StreamOptions configures a Stream call.
+type StreamOptions struct {
+	// Addr is the "host:port" of the APRS-IS server to dial. Defaults to
+	// DefaultAddr.
+	Addr string
+
+	// Callsign and Passcode authenticate the login handshake. Use a
+	// Passcode of -1 to log in as receive-only.
+	Callsign string
+	Passcode int
+
+	// Filters seed the server-side filter. Additional terms can be added
+	// or removed later with Client.Subscribe and Client.Unsubscribe.
+	Filters []Filter
+
+	// Sampling, if > 0 and < 1, randomly drops that fraction of incoming
+	// packets client-side. APRS-IS has no concept of sampling, so this is
+	// applied locally after parsing.
+	Sampling float64
+
+	// MinBackoff and MaxBackoff bound the exponential reconnect backoff.
+	// Zero values fall back to 1s and 2m.
+	MinBackoff, MaxBackoff time.Duration
+
+	// KeepAlive sets the interval at which a "#" comment line is written
+	// to the server to hold the connection open through NATs. Zero falls
+	// back to 20s.
+	KeepAlive time.Duration
+}
+
+func (o *StreamOptions) withDefaults() {
+	if o.Addr == "" {
+		o.Addr = DefaultAddr
+	}
+	if o.MinBackoff == 0 {
+		o.MinBackoff = time.Second
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = 2 * time.Minute
+	}
+	if o.KeepAlive == 0 {
+		o.KeepAlive = 20 * time.Second
+	}
+}
+
+// Client manages a reconnecting APRS-IS connection started by Stream.
+type Client struct {
+	opts   StreamOptions
+	parser hamaprs.Parser
+
+	mu      sync.Mutex
+	filters map[string]Filter
+	conn    net.Conn
+}
+
+// Stream dials opts.Addr, logs in, and returns a channel of parsed packets.
+// The client reconnects on its own with exponential backoff until ctx is
+// cancelled, at which point the channel is closed. The returned Client lets
+// a caller change the live filter set with Subscribe and Unsubscribe.
+func Stream(ctx context.Context, opts StreamOptions) (<-chan *hamaprs.Packet, *Client, error) {
+	opts.withDefaults()
+
+	c := &Client{
+		opts:    opts,
+		parser:  hamaprs.NewParser(),
+		filters: make(map[string]Filter, len(opts.Filters)),
+	}
+	for i, f := range opts.Filters {
+		c.filters[fmt.Sprintf("initial-%d", i)] = f
+	}
+
+	out := make(chan *hamaprs.Packet)
+	go c.run(ctx, out)
+	return out, c, nil
+}
+
+// Subscribe adds or replaces a named filter term and, if connected, pushes
+// the updated filter set to the server without reconnecting. id is an
+// arbitrary caller-chosen key used later to Unsubscribe.
+func (c *Client) Subscribe(id string, f Filter) {
+	c.mu.Lock()
+	c.filters[id] = f
+	c.mu.Unlock()
+	c.pushFilter()
+}
+
+// Unsubscribe removes a named filter term added by Subscribe and, if
+// connected, pushes the updated filter set to the server.
+func (c *Client) Unsubscribe(id string) {
+	c.mu.Lock()
+	delete(c.filters, id)
+	c.mu.Unlock()
+	c.pushFilter()
+}
+
+// filterString joins the current filter set into one APRS-IS filter clause.
+func (c *Client) filterString() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	terms := make([]string, 0, len(c.filters))
+	for _, f := range c.filters {
+		terms = append(terms, string(f))
+	}
+	return strings.Join(terms, " ")
+}
+
+// pushFilter sends the current filter set to the server on a live
+// connection. It is a no-op while disconnected; the up-to-date set is sent
+// as part of the next login line regardless.
+func (c *Client) pushFilter() {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	fmt.Fprintf(conn, "#filter %s\r\n", c.filterString())
+}
+
+func (c *Client) run(ctx context.Context, out chan<- *hamaprs.Packet) {
+	defer close(out)
+
+	backoff := c.opts.MinBackoff
+	resetBackoff := func() { backoff = c.opts.MinBackoff }
+	for {
+		err := c.connectAndRead(ctx, out, resetBackoff)
+		if ctx.Err() != nil {
+			return
+		}
+		_ = err // reconnect regardless of the reason; the caller watches out/ctx
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jitter(backoff)):
+		}
+		backoff *= 2
+		if backoff > c.opts.MaxBackoff {
+			backoff = c.opts.MaxBackoff
+		}
+	}
+}
+
+// connectAndRead dials once, logs in, and streams packets until the
+// connection fails or ctx is cancelled. resetBackoff is called as soon as
+// one non-comment line has been read, so a connection that dies after
+// running healthily does not inherit the backoff of the failures that
+// preceded it.
+func (c *Client) connectAndRead(ctx context.Context, out chan<- *hamaprs.Packet, resetBackoff func()) error {
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(attemptCtx, "tcp", c.opts.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	go func() {
+		<-attemptCtx.Done()
+		conn.Close()
+	}()
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", c.loginLine()); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+	}()
+
+	stop := c.startKeepAlive(conn)
+	defer stop()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), 64*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			// Server comment, including the periodic keepalive/banner lines.
+			continue
+		}
+		resetBackoff()
+		if shouldDrop(c.opts.Sampling, rand.Float64()) {
+			continue
+		}
+		packet, err := c.parser.ParsePacket(line, false)
+		if err != nil {
+			continue
+		}
+		select {
+		case out <- packet:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return scanner.Err()
+}
+
+// loginLine builds the "user CALL pass PASS vers hamaprs X.Y filter ..."
+// handshake line sent immediately after connecting.
+func (c *Client) loginLine() string {
+	line := fmt.Sprintf("user %s pass %d vers %s %s",
+		c.opts.Callsign, c.opts.Passcode, softwareName, softwareVersion)
+	if f := c.filterString(); f != "" {
+		line += " filter " + f
+	}
+	return line
+}
+
+// startKeepAlive writes a "#" comment line on conn every KeepAlive interval
+// to hold the connection open through NATs, and returns a function that
+// stops it.
+func (c *Client) startKeepAlive(conn net.Conn) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.opts.KeepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := fmt.Fprint(conn, "#\r\n"); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// shouldDrop reports whether a packet should be dropped under
+// StreamOptions.Sampling, given roll, a uniform random draw from [0, 1).
+// A roll below sampling drops the packet, so Sampling: 0.1 drops ~10% of
+// traffic.
+func shouldDrop(sampling, roll float64) bool {
+	return sampling > 0 && sampling < 1 && roll < sampling
+}
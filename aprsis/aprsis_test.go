@@ -0,0 +1,72 @@
+package aprsis
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestShouldDrop(t *testing.T) {
+	cases := []struct {
+		sampling, roll float64
+		want           bool
+	}{
+		{0, 0, false},     // sampling disabled
+		{1, 0.5, false},   // sampling disabled (out of (0,1) range)
+		{0.1, 0.05, true}, // below the sampling fraction: dropped
+		{0.1, 0.5, false}, // above the sampling fraction: kept
+		{0.9, 0.5, true},  // below the sampling fraction: dropped
+	}
+	for _, c := range cases {
+		if got := shouldDrop(c.sampling, c.roll); got != c.want {
+			t.Errorf("shouldDrop(%v, %v) = %v, want %v", c.sampling, c.roll, got, c.want)
+		}
+	}
+}
+
+// TestConnectAndReadDoesNotLeakWatcherGoroutine guards against a reconnect
+// loop (run, using the long-lived client ctx) accumulating one blocked
+// "<-ctx.Done(); conn.Close()" goroutine per attempt: each attempt must
+// cancel its own derived context when connectAndRead returns, rather than
+// waiting on the outer, rarely-cancelled ctx.
+func TestConnectAndReadDoesNotLeakWatcherGoroutine(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close() // immediately drop the connection, forcing a quick EOF
+		}
+	}()
+
+	c := &Client{opts: StreamOptions{Addr: ln.Addr().String()}}
+	c.opts.withDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Let the runtime settle before measuring the baseline.
+	time.Sleep(10 * time.Millisecond)
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 20; i++ {
+		_ = c.connectAndRead(ctx, nil, func() {})
+	}
+
+	// Give any leaked goroutines a moment to show up in the count before we
+	// assert on it.
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before+5 {
+		t.Errorf("goroutine count grew from %d to %d after 20 connectAndRead attempts; want it to stay roughly flat", before, after)
+	}
+}
@@ -0,0 +1,44 @@
+package aprsis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter is a single term of the APRS-IS server-side filter grammar
+// documented at http://www.aprs-is.net/WX/Filters.aspx, e.g.
+// "r/37.77/-122.41/50" or "t/poimntqsu".
+type Filter string
+
+// Range builds a range filter matching all stations within km kilometres of
+// (lat, lon).
+func Range(lat, lon, km float64) Filter {
+	return Filter(fmt.Sprintf("r/%s/%s/%s", trimFloat(lat), trimFloat(lon), trimFloat(km)))
+}
+
+// Prefix builds a prefix filter on one or more callsign prefixes.
+func Prefix(prefixes ...string) Filter {
+	return Filter("p/" + strings.Join(prefixes, "/"))
+}
+
+// Buddy builds a buddy filter on one or more callsigns.
+func Buddy(calls ...string) Filter {
+	return Filter("b/" + strings.Join(calls, "/"))
+}
+
+// Type builds a packet-type filter. types is any subset of "poimntqsu"
+// (position, object, item, mic-e, node/status, nws/wx, query, telemetry,
+// user-defined).
+func Type(types string) Filter {
+	return Filter("t/" + types)
+}
+
+// Symbol builds a filter on one or more symbol table/code pairs.
+func Symbol(symbols ...string) Filter {
+	return Filter("s/" + strings.Join(symbols, "/"))
+}
+
+func trimFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,144 @@
+// Package ax25 decodes and encodes AX.25 UI frames - the only frame type
+// APRS uses - as delivered by a KISS TNC: address fields, control and PID,
+// with no flags or FCS (the TNC strips those).
+package ax25
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	addrLen   = 7
+	uiControl = 0x03
+	pidNone   = 0xF0
+)
+
+// Address is one decoded AX.25 address field: a callsign, SSID and, for
+// digipeater path entries, whether this digipeater has already relayed the
+// frame (the "H bit", conventionally displayed as a trailing '*').
+type Address struct {
+	Callsign string
+	SSID     byte
+	Repeated bool
+}
+
+// String renders a in the usual "CALL-SSID*" monitor form.
+func (a Address) String() string {
+	s := a.Callsign
+	if a.SSID != 0 {
+		s += fmt.Sprintf("-%d", a.SSID)
+	}
+	if a.Repeated {
+		s += "*"
+	}
+	return s
+}
+
+// Frame is a decoded AX.25 UI frame.
+type Frame struct {
+	Destination Address
+	Source      Address
+	Path        []Address
+	PID         byte
+	Info        []byte
+}
+
+// Decode parses a raw AX.25 UI frame.
+func Decode(raw []byte) (*Frame, error) {
+	addrs, n, err := decodeAddresses(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) < 2 {
+		return nil, errors.New("ax25: frame needs at least a destination and source address")
+	}
+
+	rest := raw[n:]
+	if len(rest) < 2 {
+		return nil, errors.New("ax25: missing control/PID")
+	}
+	if rest[0] != uiControl {
+		return nil, fmt.Errorf("ax25: unsupported control byte 0x%02X, only UI frames are supported", rest[0])
+	}
+
+	return &Frame{
+		Destination: addrs[0],
+		Source:      addrs[1],
+		Path:        addrs[2:],
+		PID:         rest[1],
+		Info:        rest[2:],
+	}, nil
+}
+
+func decodeAddresses(raw []byte) ([]Address, int, error) {
+	var addrs []Address
+	for offset := 0; ; offset += addrLen {
+		if offset+addrLen > len(raw) {
+			return nil, 0, errors.New("ax25: truncated address field")
+		}
+		field := raw[offset : offset+addrLen]
+		addrs = append(addrs, Address{
+			Callsign: decodeCallsign(field[:6]),
+			SSID:     (field[6] >> 1) & 0x0F,
+			Repeated: field[6]&0x80 != 0,
+		})
+		if field[6]&0x01 != 0 { // extension bit: this was the last address field
+			return addrs, offset + addrLen, nil
+		}
+	}
+}
+
+func decodeCallsign(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if ch := c >> 1; ch != ' ' {
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
+
+// Encode renders f as a raw AX.25 UI frame, ready to hand to a KISS Writer.
+func Encode(f *Frame) []byte {
+	buf := make([]byte, 0, addrLen*(2+len(f.Path))+2+len(f.Info))
+	buf = append(buf, encodeAddress(f.Destination, false)...)
+	buf = append(buf, encodeAddress(f.Source, len(f.Path) == 0)...)
+	for i, a := range f.Path {
+		buf = append(buf, encodeAddress(a, i == len(f.Path)-1)...)
+	}
+	buf = append(buf, uiControl)
+	pid := f.PID
+	if pid == 0 {
+		pid = pidNone
+	}
+	buf = append(buf, pid)
+	buf = append(buf, f.Info...)
+	return buf
+}
+
+func encodeAddress(a Address, last bool) []byte {
+	field := make([]byte, addrLen)
+	call := a.Callsign
+	if len(call) > 6 {
+		call = call[:6]
+	}
+	for i := 0; i < 6; i++ {
+		c := byte(' ')
+		if i < len(call) {
+			c = call[i]
+		}
+		field[i] = c << 1
+	}
+
+	b := byte(0x40) | (a.SSID << 1) // bit6 is always reserved=1
+	if a.Repeated {
+		b |= 0x80
+	}
+	if last {
+		b |= 0x01
+	}
+	field[6] = b
+	return field
+}
@@ -0,0 +1,78 @@
+package ax25
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	f := &Frame{
+		Destination: Address{Callsign: "APRS"},
+		Source:      Address{Callsign: "N0CALL", SSID: 7},
+		Path: []Address{
+			{Callsign: "WIDE1", SSID: 1, Repeated: true},
+			{Callsign: "WIDE2", SSID: 2},
+		},
+		PID:  0xF0,
+		Info: []byte("!3745.00N/12225.00W>test"),
+	}
+
+	raw := Encode(f)
+	got, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.Destination != f.Destination {
+		t.Errorf("Destination = %+v, want %+v", got.Destination, f.Destination)
+	}
+	if got.Source != f.Source {
+		t.Errorf("Source = %+v, want %+v", got.Source, f.Source)
+	}
+	if len(got.Path) != len(f.Path) || got.Path[0] != f.Path[0] || got.Path[1] != f.Path[1] {
+		t.Errorf("Path = %+v, want %+v", got.Path, f.Path)
+	}
+	if got.PID != f.PID {
+		t.Errorf("PID = 0x%02X, want 0x%02X", got.PID, f.PID)
+	}
+	if !bytes.Equal(got.Info, f.Info) {
+		t.Errorf("Info = %q, want %q", got.Info, f.Info)
+	}
+}
+
+func TestAddressString(t *testing.T) {
+	cases := []struct {
+		addr Address
+		want string
+	}{
+		{Address{Callsign: "N0CALL"}, "N0CALL"},
+		{Address{Callsign: "N0CALL", SSID: 9}, "N0CALL-9"},
+		{Address{Callsign: "WIDE1", SSID: 1, Repeated: true}, "WIDE1-1*"},
+	}
+	for _, c := range cases {
+		if got := c.addr.String(); got != c.want {
+			t.Errorf("%+v.String() = %q, want %q", c.addr, got, c.want)
+		}
+	}
+}
+
+func TestDecodeRejectsNonUIControl(t *testing.T) {
+	f := &Frame{
+		Destination: Address{Callsign: "APRS"},
+		Source:      Address{Callsign: "N0CALL"},
+		PID:         0xF0,
+	}
+	raw := Encode(f)
+	// Corrupt the control byte (first byte after the two 7-byte address
+	// fields) to something other than UI (0x03).
+	raw[addrLen*2] = 0x00
+
+	if _, err := Decode(raw); err == nil {
+		t.Error("Decode with non-UI control byte: want error, got nil")
+	}
+}
+
+func TestDecodeRejectsTruncatedAddress(t *testing.T) {
+	if _, err := Decode([]byte{0x01, 0x02, 0x03}); err == nil {
+		t.Error("Decode with truncated address field: want error, got nil")
+	}
+}
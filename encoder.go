@@ -0,0 +1,589 @@
+package hamaprs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// defaultTocall is used as the AX.25 destination callsign when a Packet
+// being encoded does not set one.
+const defaultTocall = "APZHAP"
+
+// EncodeOptions controls aspects of Encode that cannot be inferred from the
+// Packet fields alone.
+type EncodeOptions struct {
+	// Compressed selects Base91 compressed position encoding for Location,
+	// Object and Item packets instead of the uncompressed DDMM.mm form.
+	Compressed bool
+	// Timestamped includes a DHM zulu timestamp on Location packets.
+	Timestamped bool
+	// Messaging marks a Location packet as messaging-capable ('=' / '@'
+	// data type instead of '!' / '/').
+	Messaging bool
+}
+
+// Encode renders p as APRS TNC2 text using the zero value of EncodeOptions.
+// It is the inverse of Parser.ParsePacket for well-formed packets.
+func (p *Packet) Encode() (string, error) {
+	return EncodePacket(p, EncodeOptions{})
+}
+
+// EncodePacket renders p as APRS TNC2 text ("SRC>DEST:payload"), the inverse
+// of Parser.ParsePacket for well-formed packets.
+func EncodePacket(p *Packet, opts EncodeOptions) (string, error) {
+	if p.SourceCallsign == "" {
+		return "", errors.New("hamaprs: Encode requires SourceCallsign")
+	}
+	body, err := encodeBody(p, opts)
+	if err != nil {
+		return "", err
+	}
+	dst := p.DestinationCallsign
+	if dst == "" {
+		dst = defaultTocall
+	}
+	return fmt.Sprintf("%s>%s:%s", strings.ToUpper(p.SourceCallsign), dst, body), nil
+}
+
+func encodeBody(p *Packet, opts EncodeOptions) (string, error) {
+	switch p.PacketType {
+	case LocationPacketType:
+		return encodeLocationBody(p, opts)
+	case ObjectPacketType:
+		return encodeObjectBody(p, opts)
+	case ItemPacketType:
+		return encodeItemBody(p, opts)
+	case StatusPacketType:
+		return encodeStatusBody(p)
+	case MessagePacketType:
+		return encodeMessageBody(p)
+	case MicePacketType:
+		return encodeMiceBody(p)
+	case WXPacketType:
+		return encodeWeatherBody(p)
+	default:
+		return "", fmt.Errorf("hamaprs: encoding packet type %v is not supported", p.PacketType)
+	}
+}
+
+// BuildLocation returns a Location Packet ready for Encode.
+func BuildLocation(src string, lat, lon float64, symbol string) *Packet {
+	return &Packet{
+		PacketType:     LocationPacketType,
+		SourceCallsign: src,
+		Latitude:       lat,
+		Longitude:      lon,
+		Symbol:         symbol,
+	}
+}
+
+// BuildObject returns an Object Packet ready for Encode. live selects
+// between a live ('*') and killed ('_') object.
+func BuildObject(src, name string, lat, lon float64, symbol string, live bool) *Packet {
+	return &Packet{
+		PacketType:     ObjectPacketType,
+		SourceCallsign: src,
+		Name:           name,
+		Latitude:       lat,
+		Longitude:      lon,
+		Symbol:         symbol,
+		Live:           live,
+	}
+}
+
+// BuildItem returns an Item Packet ready for Encode. live selects between a
+// live ('!') and killed ('_') item.
+func BuildItem(src, name string, lat, lon float64, symbol string, live bool) *Packet {
+	return &Packet{
+		PacketType:     ItemPacketType,
+		SourceCallsign: src,
+		Name:           name,
+		Latitude:       lat,
+		Longitude:      lon,
+		Symbol:         symbol,
+		Live:           live,
+	}
+}
+
+// BuildMessage returns a Message Packet addressed to addressee, ready for
+// Encode. msgNumber may be empty if no ack is wanted.
+func BuildMessage(src, addressee, message, msgNumber string) *Packet {
+	return &Packet{
+		PacketType:     MessagePacketType,
+		SourceCallsign: src,
+		Addressee:      addressee,
+		Message:        message,
+		MessageNumber:  msgNumber,
+	}
+}
+
+// BuildAck returns a message-ack Packet acknowledging msgNumber to
+// addressee.
+func BuildAck(src, addressee, msgNumber string) *Packet {
+	return &Packet{
+		PacketType:     MessagePacketType,
+		SourceCallsign: src,
+		Addressee:      addressee,
+		Message:        "ack" + msgNumber,
+	}
+}
+
+// BuildReject returns a message-reject Packet rejecting msgNumber to
+// addressee.
+func BuildReject(src, addressee, msgNumber string) *Packet {
+	return &Packet{
+		PacketType:     MessagePacketType,
+		SourceCallsign: src,
+		Addressee:      addressee,
+		Message:        "rej" + msgNumber,
+	}
+}
+
+// BuildStatus returns a Status Packet ready for Encode.
+func BuildStatus(src, status string) *Packet {
+	return &Packet{PacketType: StatusPacketType, SourceCallsign: src, Status: status}
+}
+
+// BuildWeather returns a WX Packet ready for Encode.
+func BuildWeather(src string, w WeatherReport) *Packet {
+	return &Packet{PacketType: WXPacketType, SourceCallsign: src, Weather: &w}
+}
+
+// BuildMicE returns a Mic-E Packet ready for Encode. message must be one of
+// the standard Mic-E message names, e.g. "En Route" (see micEMessageBits).
+func BuildMicE(src, message string, lat, lon float64, symbol string) *Packet {
+	return &Packet{
+		PacketType:     MicePacketType,
+		SourceCallsign: src,
+		MicE:           message,
+		Latitude:       lat,
+		Longitude:      lon,
+		Symbol:         symbol,
+	}
+}
+
+// ValidSymbol reports whether table and code form a valid APRS symbol
+// table/code pair. table must be '/' (primary) or '\\' (alternate), or an
+// overlay character ('0'-'9', 'A'-'Z') selecting the alternate table with a
+// numeric/alpha overlay. code must be a printable ASCII character.
+func ValidSymbol(table, code byte) bool {
+	validTable := table == '/' || table == '\\' ||
+		(table >= '0' && table <= '9') || (table >= 'A' && table <= 'Z')
+	validCode := code >= 0x21 && code <= 0x7E
+	return validTable && validCode
+}
+
+// symbolTableAndCode splits the 2-character Symbol field into its table and
+// code bytes, defaulting to "/>" (primary table, car) when unset.
+func (p *Packet) symbolTableAndCode() (byte, byte, error) {
+	table, code := byte('/'), byte('>')
+	if len(p.Symbol) == 2 {
+		table, code = p.Symbol[0], p.Symbol[1]
+	} else if p.Symbol != "" {
+		return 0, 0, fmt.Errorf("hamaprs: Symbol must be exactly 2 characters (table+code), got %q", p.Symbol)
+	}
+	if !ValidSymbol(table, code) {
+		return 0, 0, fmt.Errorf("hamaprs: invalid symbol table/code %q/%q", table, code)
+	}
+	return table, code, nil
+}
+
+func encodeLocationBody(p *Packet, opts EncodeOptions) (string, error) {
+	if !p.IncludePosition() {
+		return "", errors.New("hamaprs: location packet requires Latitude/Longitude")
+	}
+	table, code, err := p.symbolTableAndCode()
+	if err != nil {
+		return "", err
+	}
+
+	var dataType byte
+	switch {
+	case opts.Timestamped && opts.Messaging:
+		dataType = '@'
+	case opts.Timestamped:
+		dataType = '/'
+	case opts.Messaging:
+		dataType = '='
+	default:
+		dataType = '!'
+	}
+
+	var b strings.Builder
+	b.WriteByte(dataType)
+	if opts.Timestamped {
+		b.WriteString(dhmTimestamp(p.Timestamp))
+	}
+	if opts.Compressed {
+		b.WriteString(compressPosition(p.Latitude, p.Longitude, table, code, p.Course, p.Speed))
+	} else {
+		b.WriteString(encodeLat(p.Latitude))
+		b.WriteByte(table)
+		b.WriteString(encodeLon(p.Longitude))
+		b.WriteByte(code)
+		if p.Course != 0 || p.Speed != 0 {
+			// CSE/SPD is knots on the wire; Packet.Speed is km/h.
+			fmt.Fprintf(&b, "%03d/%03d", p.Course, int(math.Round(p.Speed/1.852)))
+		}
+	}
+	b.WriteString(commentWithExtensions(p))
+	return b.String(), nil
+}
+
+func encodeObjectBody(p *Packet, opts EncodeOptions) (string, error) {
+	if p.Name == "" {
+		return "", errors.New("hamaprs: object packet requires Name")
+	}
+	if !p.IncludePosition() {
+		return "", errors.New("hamaprs: object packet requires Latitude/Longitude")
+	}
+	table, code, err := p.symbolTableAndCode()
+	if err != nil {
+		return "", err
+	}
+	state := byte('*')
+	if !p.Live {
+		state = '_'
+	}
+
+	var b strings.Builder
+	b.WriteByte(';')
+	fmt.Fprintf(&b, "%-9s", truncate(p.Name, 9))
+	b.WriteByte(state)
+	b.WriteString(dhmTimestamp(p.Timestamp))
+	if opts.Compressed {
+		b.WriteString(compressPosition(p.Latitude, p.Longitude, table, code, p.Course, p.Speed))
+	} else {
+		b.WriteString(encodeLat(p.Latitude))
+		b.WriteByte(table)
+		b.WriteString(encodeLon(p.Longitude))
+		b.WriteByte(code)
+	}
+	b.WriteString(commentWithExtensions(p))
+	return b.String(), nil
+}
+
+func encodeItemBody(p *Packet, opts EncodeOptions) (string, error) {
+	if p.Name == "" {
+		return "", errors.New("hamaprs: item packet requires Name")
+	}
+	if !p.IncludePosition() {
+		return "", errors.New("hamaprs: item packet requires Latitude/Longitude")
+	}
+	table, code, err := p.symbolTableAndCode()
+	if err != nil {
+		return "", err
+	}
+	state := byte('!')
+	if !p.Live {
+		state = '_'
+	}
+
+	var b strings.Builder
+	b.WriteByte(')')
+	b.WriteString(truncate(p.Name, 9))
+	b.WriteByte(state)
+	if opts.Compressed {
+		b.WriteString(compressPosition(p.Latitude, p.Longitude, table, code, 0, 0))
+	} else {
+		b.WriteString(encodeLat(p.Latitude))
+		b.WriteByte(table)
+		b.WriteString(encodeLon(p.Longitude))
+		b.WriteByte(code)
+	}
+	b.WriteString(commentWithExtensions(p))
+	return b.String(), nil
+}
+
+func encodeStatusBody(p *Packet) (string, error) {
+	return ">" + p.Status, nil
+}
+
+func encodeMessageBody(p *Packet) (string, error) {
+	if p.Addressee == "" {
+		return "", errors.New("hamaprs: message packet requires Addressee")
+	}
+	body := fmt.Sprintf(":%-9s:%s", strings.ToUpper(truncate(p.Addressee, 9)), p.Message)
+	if p.MessageNumber != "" {
+		body += "{" + p.MessageNumber
+	}
+	return body, nil
+}
+
+// encodeWeatherBody renders a positionless weather report: DTI '_', an
+// 8-digit MMDDHHMM timestamp (no zone letter, unlike the 6-digit DHM used
+// elsewhere), then wind/gust/temperature/humidity/pressure fields. Every
+// WX packet is rendered this way: Latitude/Longitude are not encoded, and
+// encodeLocationBody writes no Weather fields, so a combined
+// position+weather report is not produced.
+func encodeWeatherBody(p *Packet) (string, error) {
+	if p.Weather == nil {
+		return "", errors.New("hamaprs: weather packet requires Weather")
+	}
+	w := p.Weather
+	var b strings.Builder
+	b.WriteByte('_')
+	b.WriteString(mdhmTimestamp(p.Timestamp))
+	fmt.Fprintf(&b, "c%03d", w.WindDirection)
+	fmt.Fprintf(&b, "s%03d", int(math.Round(w.WindSpeed)))
+	fmt.Fprintf(&b, "g%03d", int(math.Round(w.WindGust)))
+	b.WriteByte('t')
+	b.WriteString(fmtSigned3(int(math.Round(w.Temperature))))
+	if w.Humidity > 0 {
+		fmt.Fprintf(&b, "h%02d", w.Humidity%100)
+	}
+	if w.Pressure > 0 {
+		fmt.Fprintf(&b, "b%05d", int(math.Round(w.Pressure*10)))
+	}
+	b.WriteString(p.Comment)
+	return b.String(), nil
+}
+
+// micEMessageBits maps the standard Mic-E message names (as produced by
+// fap_mice_mbits_to_message and stored in Packet.MicE) to the three
+// message-indicator bits encoded into the first three Mic-E destination
+// callsign characters. Custom message types and position ambiguity are not
+// supported.
+var micEMessageBits = map[string][3]byte{
+	"Off Duty":   {1, 1, 1},
+	"En Route":   {1, 1, 0},
+	"In Service": {1, 0, 1},
+	"Returning":  {1, 0, 0},
+	"Committed":  {0, 1, 1},
+	"Special":    {0, 1, 0},
+	"Priority":   {0, 0, 1},
+	"Emergency":  {0, 0, 0},
+}
+
+// encodeMiceBody renders p as a Mic-E information field. The AX.25
+// destination callsign it derives from p.Latitude/p.Longitude/p.MicE is
+// written back to p.DestinationCallsign, since that is where Mic-E actually
+// carries its position.
+func encodeMiceBody(p *Packet) (string, error) {
+	if !p.IncludePosition() {
+		return "", errors.New("hamaprs: mic-e packet requires Latitude/Longitude")
+	}
+	bits, ok := micEMessageBits[p.MicE]
+	if !ok {
+		return "", fmt.Errorf("hamaprs: unknown Mic-E message %q", p.MicE)
+	}
+	table, code, err := p.symbolTableAndCode()
+	if err != nil {
+		return "", err
+	}
+
+	dest, err := micEDestCallsign(p.Latitude, p.Longitude, bits)
+	if err != nil {
+		return "", err
+	}
+	p.DestinationCallsign = dest
+
+	var b strings.Builder
+	b.WriteByte('`')
+	b.WriteString(micELongitude(p.Longitude))
+	b.WriteString(compressedCourseSpeed(p.Course, p.Speed))
+	b.WriteByte(code)
+	b.WriteByte(table)
+	b.WriteString(p.Comment)
+	return b.String(), nil
+}
+
+// micEDestCallsign builds the 6-character latitude/message-bit field carried
+// in the Mic-E AX.25 destination callsign. North is signalled by offsetting
+// digit 4 into 'A'-'J'; west by offsetting digit 6 the same way.
+func micEDestCallsign(lat, lon float64, bits [3]byte) (string, error) {
+	north := lat >= 0
+	west := lon < 0
+	abs := math.Abs(lat)
+	deg := int(abs)
+	minutes := (abs - float64(deg)) * 60
+	digits := [6]int{
+		deg / 10,
+		deg % 10,
+		int(minutes) / 10,
+		int(minutes) % 10,
+		int(minutes*100) / 10 % 10,
+		int(math.Round(minutes*100)) % 10,
+	}
+
+	dest := make([]byte, 6)
+	for i := 0; i < 3; i++ {
+		dest[i] = micEDigit(digits[i], bits[i])
+	}
+	dest[3] = micEFlagDigit(digits[3], north)
+	dest[4] = byte('0' + digits[4]) // longitude >=100 offset is carried in micELongitude
+	dest[5] = micEFlagDigit(digits[5], west)
+	return string(dest), nil
+}
+
+func micEDigit(digit int, bit byte) byte {
+	if bit == 0 {
+		return byte('0' + digit)
+	}
+	return byte('P' + digit)
+}
+
+func micEFlagDigit(digit int, flag bool) byte {
+	if !flag {
+		return byte('0' + digit)
+	}
+	return byte('A' + digit)
+}
+
+// micELongitude packs longitude into the 3-byte Mic-E format: degrees,
+// minutes and hundredths of a minute. Minutes and hundredths are offset by
+// 28 so they land in printable ASCII; degrees additionally set bit 7 when
+// the true degree value is 100 or more, since the AX.25 destination
+// callsign alone cannot carry a degree value past 99.
+func micELongitude(lon float64) string {
+	abs := math.Abs(lon)
+	deg := int(abs)
+	offset100 := deg >= 100
+	if offset100 {
+		deg -= 100
+	}
+	minutes := (abs - math.Floor(abs)) * 60
+	minInt := int(minutes)
+	hundredths := int(math.Round((minutes - float64(minInt)) * 100))
+
+	degByte := deg + 28
+	if offset100 {
+		degByte += 128
+	}
+	return string([]byte{byte(degByte), byte(minInt + 28), byte(hundredths + 28)})
+}
+
+// compressPosition renders lat/lon in the Base91 compressed position format
+// shared by Location/Object/Item packets (APRS spec 1.2 section 9).
+func compressPosition(lat, lon float64, table, code byte, course uint8, speed float64) string {
+	latVal := int64(math.Round(380926 * (90 - lat)))
+	lonVal := int64(math.Round(190463 * (180 + lon)))
+
+	var b strings.Builder
+	b.WriteByte(table)
+	b.WriteString(base91Encode(latVal, 4))
+	b.WriteString(base91Encode(lonVal, 4))
+	b.WriteByte(code)
+	b.WriteString(compressedCourseSpeed(course, speed))
+	b.WriteByte('!') // compression type: current GPS fix, no specific NMEA source
+	return b.String()
+}
+
+// compressedCourseSpeed packs course/speed into the 2-byte form used by both
+// compressed positions and Mic-E frames.
+func compressedCourseSpeed(course uint8, speedKmh float64) string {
+	if course == 0 && speedKmh == 0 {
+		return "  "
+	}
+	cs := int(math.Round(float64(course) / 4))
+	if cs > 89 {
+		cs = 89
+	}
+	speedKnots := speedKmh / 1.852
+	sp := 0
+	if speedKnots > 0 {
+		sp = int(math.Round(math.Log(speedKnots+1) / math.Log(1.08)))
+	}
+	if sp > 89 {
+		sp = 89
+	}
+	return string([]byte{byte(33 + cs), byte(33 + sp)})
+}
+
+func base91Encode(v int64, width int) string {
+	chars := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		chars[i] = byte(v%91) + 33
+		v /= 91
+	}
+	return string(chars)
+}
+
+func encodeLat(lat float64) string {
+	hemi := byte('N')
+	if lat < 0 {
+		hemi = 'S'
+		lat = -lat
+	}
+	deg := int(lat)
+	min := (lat - float64(deg)) * 60
+	return fmt.Sprintf("%02d%05.2f%c", deg, min, hemi)
+}
+
+func encodeLon(lon float64) string {
+	hemi := byte('E')
+	if lon < 0 {
+		hemi = 'W'
+		lon = -lon
+	}
+	deg := int(lon)
+	min := (lon - float64(deg)) * 60
+	return fmt.Sprintf("%03d%05.2f%c", deg, min, hemi)
+}
+
+func dhmTimestamp(unix int) string {
+	t := time.Unix(int64(unix), 0).UTC()
+	return fmt.Sprintf("%02d%02d%02dz", t.Day(), t.Hour(), t.Minute())
+}
+
+// mdhmTimestamp formats the 8-digit MMDDHHMM timestamp (no zone letter)
+// used by a positionless weather report.
+func mdhmTimestamp(unix int) string {
+	t := time.Unix(int64(unix), 0).UTC()
+	return fmt.Sprintf("%02d%02d%02d%02d", t.Month(), t.Day(), t.Hour(), t.Minute())
+}
+
+// commentWithExtensions appends the /A= altitude and !W..! DAO extensions
+// (when applicable) to a packet's free-text comment.
+func commentWithExtensions(p *Packet) string {
+	var b strings.Builder
+	b.WriteString(p.Comment)
+	if p.Altitude != 0 {
+		b.WriteString(altitudeExtension(p.Altitude))
+	}
+	if p.IncludePosition() {
+		b.WriteString(daoExtension(p.Latitude, p.Longitude))
+	}
+	return b.String()
+}
+
+// altitudeExtension returns the "/A=ffffff" comment extension, altitude in
+// feet zero-padded to 6 digits.
+func altitudeExtension(meters float64) string {
+	feet := int(math.Round(meters * 3.28084))
+	if feet < 0 {
+		feet = 0
+	}
+	return fmt.Sprintf("/A=%06d", feet)
+}
+
+// daoExtension returns the human-readable "!Wxy!" DAO datum extension that
+// recovers the extra minute-digit of precision lost when lat/lon are
+// rounded to hundredths of a minute.
+func daoExtension(lat, lon float64) string {
+	return fmt.Sprintf("!W%d%d!", daoDigit(lat), daoDigit(lon))
+}
+
+func daoDigit(coord float64) int {
+	abs := math.Abs(coord)
+	minutes := (abs - math.Floor(abs)) * 60
+	thousandths := int(math.Round(minutes * 1000))
+	return thousandths % 10
+}
+
+func fmtSigned3(v int) string {
+	if v < 0 {
+		return fmt.Sprintf("-%02d", -v)
+	}
+	return fmt.Sprintf("%03d", v)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
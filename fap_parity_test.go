@@ -0,0 +1,93 @@
+//go:build cgo && fap
+
+package hamaprs
+
+import "testing"
+
+// captures is a corpus of real-world APRS-IS traffic covering the grammar
+// both backends must agree on: uncompressed and compressed position,
+// Mic-E, and weather (positionless and combined with position). It also
+// seeds FuzzFapNativeParity.
+var captures = []string{
+	"N0CALL>APRS:!3745.00N/12225.00W>088/042Comment",
+	"N0CALL>APRS:!/5L!!<*e7>7P[Comment",
+	"N0CALL-9>APU25N:`c6?l!\"OvE/En Route",
+	"N0CALL>APRS:@092345z3745.00N/12225.00W_090/005g010t072r000p000P000h50b10150",
+	"N0CALL>APRS:_10090556c220s004g005t077r000p000P000h50b09900",
+	"N0CALL>APRS:$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A",
+}
+
+// checkParity compares the fields both backends are expected to agree on
+// and reports any mismatch through t.
+func checkParity(t *testing.T, raw string, got, want *Packet) {
+	t.Helper()
+	if got.PacketType != want.PacketType {
+		t.Errorf("%q: PacketType mismatch: native %v vs fap %v", raw, got.PacketType, want.PacketType)
+	}
+	if !approxEqual(got.Latitude, want.Latitude, 0.01) || !approxEqual(got.Longitude, want.Longitude, 0.01) {
+		t.Errorf("%q: position mismatch: native (%v,%v) vs fap (%v,%v)",
+			raw, got.Latitude, got.Longitude, want.Latitude, want.Longitude)
+	}
+	if !approxEqual(got.Speed, want.Speed, 2) {
+		t.Errorf("%q: Speed mismatch: native %v km/h vs fap %v km/h", raw, got.Speed, want.Speed)
+	}
+	if got.Course != want.Course {
+		t.Errorf("%q: Course mismatch: native %v vs fap %v", raw, got.Course, want.Course)
+	}
+	if (got.Weather == nil) != (want.Weather == nil) {
+		t.Errorf("%q: Weather presence mismatch: native %v vs fap %v", raw, got.Weather, want.Weather)
+	} else if got.Weather != nil {
+		if !approxEqual(got.Weather.Temperature, want.Weather.Temperature, 1) ||
+			got.Weather.Humidity != want.Weather.Humidity ||
+			!approxEqual(got.Weather.Pressure, want.Weather.Pressure, 0.5) {
+			t.Errorf("%q: Weather mismatch: native %+v vs fap %+v", raw, got.Weather, want.Weather)
+		}
+	}
+}
+
+// TestFapNativeParity cross-checks the libfap-backed and pure-Go parsers on
+// the same captures, run only in build configurations that have libfap
+// available (cgo and the "fap" tag).
+func TestFapNativeParity(t *testing.T) {
+	fap := NewFapParser()
+	native := NewNativeParser()
+
+	for _, raw := range captures {
+		want, err := fap.ParsePacket(raw, false)
+		if err != nil {
+			t.Errorf("fap.ParsePacket(%q): %v", raw, err)
+			continue
+		}
+		got, err := native.ParsePacket(raw, false)
+		if err != nil {
+			t.Errorf("native.ParsePacket(%q): %v", raw, err)
+			continue
+		}
+		checkParity(t, raw, got, want)
+	}
+}
+
+// FuzzFapNativeParity mutates the captures corpus and requires the two
+// backends to agree on PacketType, position, course/speed and weather for
+// every input both accept. Inputs that either backend rejects are not
+// compared.
+func FuzzFapNativeParity(f *testing.F) {
+	for _, c := range captures {
+		f.Add(c)
+	}
+	fap := NewFapParser()
+	native := NewNativeParser()
+	f.Fuzz(func(t *testing.T, raw string) {
+		want, wantErr := fap.ParsePacket(raw, false)
+		got, gotErr := native.ParsePacket(raw, false)
+		if (wantErr == nil) != (gotErr == nil) {
+			// The two backends are not required to agree on exactly which
+			// malformed inputs to reject, only on well-formed ones.
+			return
+		}
+		if wantErr != nil {
+			return
+		}
+		checkParity(t, raw, got, want)
+	})
+}
@@ -0,0 +1,153 @@
+// Package kiss implements the KISS TNC framing protocol (Derived From
+// Simple), the serial/TCP byte protocol used by Direwolf, TNC-Pi and most
+// hardware TNCs to carry AX.25 frames between a TNC and a host.
+package kiss
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Special bytes of the KISS framing protocol.
+const (
+	FEND  = 0xC0 // frame end
+	FESC  = 0xDB // frame escape
+	TFEND = 0xDC // escaped FEND
+	TFESC = 0xDD // escaped FESC
+)
+
+// Command is the low nibble of a KISS frame's first byte; the high nibble
+// selects which TNC port (0-15) the command applies to.
+type Command byte
+
+// Standard KISS commands (see the KISS protocol spec).
+const (
+	CommandData        Command = 0x00
+	CommandTXDelay     Command = 0x01
+	CommandP           Command = 0x02
+	CommandSlotTime    Command = 0x03
+	CommandTXTail      Command = 0x04
+	CommandFullDuplex  Command = 0x05
+	CommandSetHardware Command = 0x06
+	CommandReturn      Command = 0xFF
+)
+
+// Frame is one decoded KISS frame.
+type Frame struct {
+	Port    byte
+	Command Command
+	Payload []byte
+}
+
+// Encode frames payload as a KISS frame: FEND, a command byte combining
+// port and cmd, the FESC-transposed payload, and a trailing FEND.
+func Encode(port byte, cmd Command, payload []byte) []byte {
+	buf := make([]byte, 0, len(payload)+4)
+	buf = append(buf, FEND)
+	buf = append(buf, port<<4|byte(cmd))
+	buf = append(buf, escape(payload)...)
+	buf = append(buf, FEND)
+	return buf
+}
+
+func escape(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		switch c {
+		case FEND:
+			out = append(out, FESC, TFEND)
+		case FESC:
+			out = append(out, FESC, TFESC)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func unescape(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == FESC && i+1 < len(b) {
+			i++
+			switch b[i] {
+			case TFEND:
+				out = append(out, FEND)
+			case TFESC:
+				out = append(out, FESC)
+			default:
+				out = append(out, b[i])
+			}
+			continue
+		}
+		out = append(out, b[i])
+	}
+	return out
+}
+
+// Reader decodes a byte stream into KISS frames.
+type Reader struct {
+	br *bufio.Reader
+}
+
+// NewReader returns a Reader that decodes KISS frames from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r)}
+}
+
+// ReadFrame blocks until one complete KISS frame has been read. Stray
+// leading FEND bytes, including the repeated FENDs some TNCs emit between
+// frames, are skipped.
+func (r *Reader) ReadFrame() (Frame, error) {
+	for {
+		b, err := r.br.ReadByte()
+		if err != nil {
+			return Frame{}, err
+		}
+		if b == FEND {
+			break
+		}
+	}
+
+	var raw []byte
+	for {
+		b, err := r.br.ReadByte()
+		if err != nil {
+			return Frame{}, err
+		}
+		if b == FEND {
+			if len(raw) == 0 {
+				continue // repeated leading FEND
+			}
+			break
+		}
+		raw = append(raw, b)
+	}
+
+	data := unescape(raw)
+	if len(data) == 0 {
+		return Frame{}, errors.New("kiss: empty frame")
+	}
+	return Frame{
+		Port:    data[0] >> 4,
+		Command: Command(data[0] & 0x0F),
+		Payload: data[1:],
+	}, nil
+}
+
+// Writer encodes KISS frames onto an underlying io.Writer.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer that encodes KISS frames onto w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteFrame encodes and writes one KISS frame.
+func (w *Writer) WriteFrame(port byte, cmd Command, payload []byte) error {
+	_, err := w.w.Write(Encode(port, cmd, payload))
+	return err
+}
@@ -0,0 +1,67 @@
+package kiss
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	payload := []byte{0x01, FEND, 0x02, FESC, 0x03}
+
+	frame := Encode(1, CommandData, payload)
+
+	r := NewReader(bytes.NewReader(frame))
+	got, err := r.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame: %v", err)
+	}
+	if got.Port != 1 {
+		t.Errorf("Port = %d, want 1", got.Port)
+	}
+	if got.Command != CommandData {
+		t.Errorf("Command = %v, want CommandData", got.Command)
+	}
+	if !bytes.Equal(got.Payload, payload) {
+		t.Errorf("Payload = %v, want %v", got.Payload, payload)
+	}
+}
+
+func TestEscapeUnescape(t *testing.T) {
+	raw := []byte{FEND, 0x01, FESC, 0x02}
+	got := unescape(escape(raw))
+	if !bytes.Equal(got, raw) {
+		t.Errorf("unescape(escape(%v)) = %v, want %v", raw, got, raw)
+	}
+}
+
+func TestReaderSkipsRepeatedLeadingFEND(t *testing.T) {
+	var stream []byte
+	stream = append(stream, FEND, FEND, FEND)
+	stream = append(stream, Encode(0, CommandData, []byte("hi"))...)
+
+	r := NewReader(bytes.NewReader(stream))
+	got, err := r.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame: %v", err)
+	}
+	if string(got.Payload) != "hi" {
+		t.Errorf("Payload = %q, want %q", got.Payload, "hi")
+	}
+}
+
+func TestWriterWriteFrame(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WriteFrame(2, CommandData, []byte("test")); err != nil {
+		t.Fatalf("WriteFrame: %v", err)
+	}
+
+	r := NewReader(&buf)
+	got, err := r.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame: %v", err)
+	}
+	if got.Port != 2 || string(got.Payload) != "test" {
+		t.Errorf("got %+v, want Port=2 Payload=\"test\"", got)
+	}
+}
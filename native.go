@@ -0,0 +1,602 @@
+package hamaprs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nativeParser implements Parser in pure Go, without cgo or libfap. It
+// covers the APRS 1.0.1/1.2 grammar: uncompressed and Base91-compressed
+// positions, Mic-E, timestamped and objectless position reports, the
+// "!"/"="/"/"/"@" position prefixes, weather, messages (including the
+// "{seq" ack suffix and telemetry metadata), status, DX spots and NMEA
+// sentences. It has no external dependencies, so it works anywhere Go
+// cross-compiles, including Windows, ARM containers and WASM.
+type nativeParser struct{}
+
+// NewNativeParser returns a Parser backed by the pure-Go grammar above.
+func NewNativeParser() Parser {
+	return &nativeParser{}
+}
+
+func (p *nativeParser) ParsePacket(raw string, isAX25 bool) (*Packet, error) {
+	packet := &Packet{Latitude: InvalidCoordinate, Longitude: InvalidCoordinate}
+	return p.FillAprsPacket(raw, isAX25, packet)
+}
+
+func (p *nativeParser) FillAprsPacket(raw string, isAX25 bool, packet *Packet) (*Packet, error) {
+	header, body, ok := strings.Cut(raw, ":")
+	if !ok {
+		return nil, errors.New("hamaprs: missing ':' separating header from body")
+	}
+	src, destPath, ok := strings.Cut(header, ">")
+	if !ok {
+		return nil, errors.New("hamaprs: missing '>' in source/destination header")
+	}
+	dest, _, _ := strings.Cut(destPath, ",")
+	if src == "" || dest == "" {
+		return nil, errors.New("hamaprs: empty source or destination callsign")
+	}
+	if body == "" {
+		return nil, errors.New("hamaprs: empty packet body")
+	}
+
+	packet.Timestamp = int(time.Now().Unix())
+	packet.SourceCallsign = strings.ToUpper(src)
+	packet.DestinationCallsign = strings.ToUpper(dest)
+	packet.RawMessage = raw
+
+	if err := parseBody(body, packet); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+func parseBody(body string, packet *Packet) error {
+	switch body[0] {
+	case '!', '=':
+		packet.PacketType = LocationPacketType
+		return parsePosition(body[1:], packet)
+	case '/', '@':
+		if len(body) < 8 {
+			return errors.New("hamaprs: malformed timestamped position")
+		}
+		packet.PacketType = LocationPacketType
+		packet.Timestamp = parseDHMTimestamp(body[1:8])
+		return parsePosition(body[8:], packet)
+	case ';':
+		packet.PacketType = ObjectPacketType
+		return parseObject(body[1:], packet)
+	case ')':
+		packet.PacketType = ItemPacketType
+		return parseItem(body[1:], packet)
+	case '>':
+		text := body[1:]
+		if strings.HasPrefix(text, "DX de ") {
+			packet.PacketType = DXSpotPacketType
+		} else {
+			packet.PacketType = StatusPacketType
+		}
+		packet.Status = text
+		return nil
+	case ':':
+		return parseMessage(body[1:], packet)
+	case '`', '\'':
+		packet.PacketType = MicePacketType
+		return parseMicE(body, packet)
+	case '_':
+		if len(body) < 9 {
+			return errors.New("hamaprs: malformed positionless weather report")
+		}
+		packet.PacketType = WXPacketType
+		packet.Timestamp = parseMDHMTimestamp(body[1:9])
+		return parseWeather(body[9:], packet)
+	case 'T':
+		if !strings.HasPrefix(body, "T#") {
+			break
+		}
+		packet.PacketType = TelemetryPacketType
+		if t, ok := parseTelemetry(packet.RawMessage); ok {
+			packet.Telemetry = &t
+		}
+		return nil
+	case '$':
+		packet.PacketType = NMEAPacketType
+		packet.Comment = body
+		parseNMEA(body, packet)
+		return nil
+	case '<':
+		packet.PacketType = CapabilitiesPacketType
+		packet.Comment = body[1:]
+		return nil
+	}
+	packet.PacketType = InvalidPacketType
+	return fmt.Errorf("hamaprs: unrecognized data type indicator %q", body[0:1])
+}
+
+// parsePosition decodes a Location/Object/Item position body: either
+// uncompressed ("DDMM.mmN/DDDMM.mmWsym...") or Base91 compressed
+// ("/YYYYXXXXsym...").
+func parsePosition(rest string, packet *Packet) error {
+	if rest == "" {
+		return errors.New("hamaprs: empty position")
+	}
+	if rest[0] >= '0' && rest[0] <= '9' {
+		return parseUncompressedPosition(rest, packet)
+	}
+	return parseCompressedPosition(rest, packet)
+}
+
+func parseUncompressedPosition(rest string, packet *Packet) error {
+	if len(rest) < 19 {
+		return errors.New("hamaprs: uncompressed position too short")
+	}
+	lat, err := decodeLat(rest[0:8])
+	if err != nil {
+		return err
+	}
+	lon, err := decodeLon(rest[9:18])
+	if err != nil {
+		return err
+	}
+	packet.Latitude = lat
+	packet.Longitude = lon
+	packet.Symbol = string([]byte{rest[8], rest[18]})
+
+	remainder := rest[19:]
+	if rest[18] == '_' {
+		// Weather station symbol: the CSE/SPD-shaped field and everything
+		// after it is wind/weather data, not course/speed.
+		if packet.PacketType == LocationPacketType {
+			packet.PacketType = WXPacketType
+		}
+		return parseWeather(remainder, packet)
+	}
+	if len(remainder) >= 7 && remainder[3] == '/' {
+		if course, err := strconv.Atoi(remainder[0:3]); err == nil {
+			packet.Course = uint8(course)
+		}
+		if speed, err := strconv.ParseFloat(remainder[4:7], 64); err == nil {
+			packet.Speed = speed * 1.852 // knots on the wire; Packet.Speed is km/h
+		}
+		remainder = remainder[7:]
+	}
+	applyCommentExtensions(remainder, packet)
+	return nil
+}
+
+func parseCompressedPosition(rest string, packet *Packet) error {
+	if len(rest) < 13 {
+		return errors.New("hamaprs: compressed position too short")
+	}
+	packet.Latitude = 90 - float64(base91Decode(rest[1:5]))/380926
+	packet.Longitude = float64(base91Decode(rest[5:9]))/190463 - 180
+	packet.Symbol = string([]byte{rest[0], rest[9]})
+
+	if rest[9] == '_' {
+		// Weather station symbol: the compressed course/speed bytes carry
+		// no defined meaning here, so skip them and parse the rest as wx.
+		if packet.PacketType == LocationPacketType {
+			packet.PacketType = WXPacketType
+		}
+		return parseWeather(rest[13:], packet)
+	}
+	if cs := rest[10:12]; cs != "  " {
+		course, speed := decodeCompressedCourseSpeed(cs)
+		packet.Course = course
+		packet.Speed = speed
+	}
+	applyCommentExtensions(rest[13:], packet)
+	return nil
+}
+
+func decodeLat(s string) (float64, error) {
+	if len(s) != 8 {
+		return 0, errors.New("hamaprs: malformed latitude")
+	}
+	deg, err := strconv.Atoi(s[0:2])
+	if err != nil {
+		return 0, fmt.Errorf("hamaprs: malformed latitude degrees: %w", err)
+	}
+	min, err := strconv.ParseFloat(s[2:7], 64)
+	if err != nil {
+		return 0, fmt.Errorf("hamaprs: malformed latitude minutes: %w", err)
+	}
+	lat := float64(deg) + min/60
+	switch s[7] {
+	case 'N':
+	case 'S':
+		lat = -lat
+	default:
+		return 0, fmt.Errorf("hamaprs: invalid latitude hemisphere %q", s[7])
+	}
+	return lat, nil
+}
+
+func decodeLon(s string) (float64, error) {
+	if len(s) != 9 {
+		return 0, errors.New("hamaprs: malformed longitude")
+	}
+	deg, err := strconv.Atoi(s[0:3])
+	if err != nil {
+		return 0, fmt.Errorf("hamaprs: malformed longitude degrees: %w", err)
+	}
+	min, err := strconv.ParseFloat(s[3:8], 64)
+	if err != nil {
+		return 0, fmt.Errorf("hamaprs: malformed longitude minutes: %w", err)
+	}
+	lon := float64(deg) + min/60
+	switch s[8] {
+	case 'E':
+	case 'W':
+		lon = -lon
+	default:
+		return 0, fmt.Errorf("hamaprs: invalid longitude hemisphere %q", s[8])
+	}
+	return lon, nil
+}
+
+// parseNMEA extracts position (and, for $GPRMC, course/speed) out of a raw
+// NMEA sentence carried in an APRS "$..." packet. Unrecognized sentence
+// types, and malformed fields within a recognized one, are left alone:
+// the caller has already stashed the raw sentence in Packet.Comment, and a
+// single bad field shouldn't take down the rest of the packet.
+func parseNMEA(body string, packet *Packet) {
+	fields := strings.Split(body, ",")
+	switch fields[0] {
+	case "$GPGGA":
+		if len(fields) < 10 {
+			return
+		}
+		lat, err := decodeNMEACoordinate(fields[2], fields[3], 2)
+		if err != nil {
+			return
+		}
+		lon, err := decodeNMEACoordinate(fields[4], fields[5], 3)
+		if err != nil {
+			return
+		}
+		alt, err := strconv.ParseFloat(fields[9], 64)
+		if err != nil {
+			return
+		}
+		packet.Latitude = lat
+		packet.Longitude = lon
+		packet.Altitude = alt
+	case "$GPRMC":
+		if len(fields) < 9 {
+			return
+		}
+		lat, err := decodeNMEACoordinate(fields[3], fields[4], 2)
+		if err != nil {
+			return
+		}
+		lon, err := decodeNMEACoordinate(fields[5], fields[6], 3)
+		if err != nil {
+			return
+		}
+		speedKnots, err := strconv.ParseFloat(fields[7], 64)
+		if err != nil {
+			return
+		}
+		course, err := strconv.ParseFloat(fields[8], 64)
+		if err != nil {
+			return
+		}
+		packet.Latitude = lat
+		packet.Longitude = lon
+		packet.Speed = speedKnots * 1.852
+		packet.Course = uint8(course)
+	}
+}
+
+// decodeNMEACoordinate parses an NMEA "ddmm.mmmm"/"dddmm.mmmm" value (degWidth
+// digits of degrees followed by minutes) and its "N"/"S"/"E"/"W" hemisphere
+// field.
+func decodeNMEACoordinate(val, hemi string, degWidth int) (float64, error) {
+	if len(val) <= degWidth {
+		return 0, errors.New("hamaprs: malformed NMEA coordinate")
+	}
+	deg, err := strconv.Atoi(val[:degWidth])
+	if err != nil {
+		return 0, fmt.Errorf("hamaprs: malformed NMEA coordinate degrees: %w", err)
+	}
+	min, err := strconv.ParseFloat(val[degWidth:], 64)
+	if err != nil {
+		return 0, fmt.Errorf("hamaprs: malformed NMEA coordinate minutes: %w", err)
+	}
+	coord := float64(deg) + min/60
+	switch hemi {
+	case "N", "E":
+	case "S", "W":
+		coord = -coord
+	default:
+		return 0, fmt.Errorf("hamaprs: invalid NMEA hemisphere %q", hemi)
+	}
+	return coord, nil
+}
+
+// applyCommentExtensions pulls the /A= altitude and !W..! DAO extensions out
+// of a trailing comment, leaving the remainder as Packet.Comment.
+func applyCommentExtensions(s string, packet *Packet) {
+	if idx := strings.Index(s, "/A="); idx != -1 && idx+9 <= len(s) {
+		if feet, err := strconv.Atoi(s[idx+3 : idx+9]); err == nil {
+			packet.Altitude = float64(feet) / 3.28084
+			s = s[:idx] + s[idx+9:]
+		}
+	}
+	if idx := strings.Index(s, "!W"); idx != -1 && idx+5 <= len(s) && s[idx+4] == '!' {
+		s = s[:idx] + s[idx+5:] // extra DAO precision digits are not applied back to Latitude/Longitude
+	}
+	packet.Comment = s
+}
+
+func parseObject(rest string, packet *Packet) error {
+	if len(rest) < 17 {
+		return errors.New("hamaprs: malformed object")
+	}
+	packet.Name = strings.TrimRight(rest[0:9], " ")
+	packet.Live = rest[9] == '*'
+	packet.Timestamp = parseDHMTimestamp(rest[10:17])
+	return parsePosition(rest[17:], packet)
+}
+
+func parseItem(rest string, packet *Packet) error {
+	idx := strings.IndexAny(rest, "!_")
+	if idx == -1 || idx > 9 {
+		return errors.New("hamaprs: malformed item, missing '!'/'_' name terminator")
+	}
+	packet.Name = rest[:idx]
+	packet.Live = rest[idx] == '!'
+	return parsePosition(rest[idx+1:], packet)
+}
+
+// parseDHMTimestamp decodes a "DDHHMMz" zulu timestamp. The month and year
+// are not present in APRS timestamps, so they are assumed to be the current
+// ones, as libfap itself does.
+func parseDHMTimestamp(s string) int {
+	day, errDay := strconv.Atoi(s[0:2])
+	hour, errHour := strconv.Atoi(s[2:4])
+	min, errMin := strconv.Atoi(s[4:6])
+	if errDay != nil || errHour != nil || errMin != nil {
+		return int(time.Now().Unix())
+	}
+	now := time.Now().UTC()
+	return int(time.Date(now.Year(), now.Month(), day, hour, min, 0, 0, time.UTC).Unix())
+}
+
+// parseMDHMTimestamp decodes the 8-digit "MMDDHHMM" timestamp (no zone
+// letter) that prefixes a positionless weather report, as distinct from the
+// 6-digit DHM timestamp used elsewhere.
+func parseMDHMTimestamp(s string) int {
+	month, errMonth := strconv.Atoi(s[0:2])
+	day, errDay := strconv.Atoi(s[2:4])
+	hour, errHour := strconv.Atoi(s[4:6])
+	min, errMin := strconv.Atoi(s[6:8])
+	if errMonth != nil || errDay != nil || errHour != nil || errMin != nil {
+		return int(time.Now().Unix())
+	}
+	now := time.Now().UTC()
+	return int(time.Date(now.Year(), time.Month(month), day, hour, min, 0, 0, time.UTC).Unix())
+}
+
+func parseMessage(rest string, packet *Packet) error {
+	if len(rest) < 10 || rest[9] != ':' {
+		return errors.New("hamaprs: malformed message, expected a 9-character addressee")
+	}
+	packet.Addressee = strings.TrimSpace(rest[0:9])
+
+	content := rest[10:]
+	if msg, seq, hasSeq := strings.Cut(content, "{"); hasSeq {
+		content = msg
+		packet.MessageNumber = seq
+	}
+	packet.Message = content
+
+	switch {
+	case strings.HasPrefix(content, "PARM."), strings.HasPrefix(content, "UNIT."),
+		strings.HasPrefix(content, "EQNS."), strings.HasPrefix(content, "BITS."):
+		packet.PacketType = TelemetryMessagePacketType
+	default:
+		packet.PacketType = MessagePacketType
+	}
+	return nil
+}
+
+// parseWeather decodes the wind/gust/temperature/humidity/pressure fields of
+// a weather report. rest is either the "CCC/SSS..." remainder of a
+// combined position+weather packet (symbol code '_'), or the "cCCCsSSS..."
+// remainder of a positionless one (DTI '_') once its leading MMDDHHMM
+// timestamp has already been stripped by the caller.
+func parseWeather(rest string, packet *Packet) error {
+	w := &WeatherReport{}
+	if len(rest) >= 7 && rest[3] == '/' {
+		if dir, err := strconv.Atoi(rest[0:3]); err == nil {
+			w.WindDirection = uint8(dir)
+		}
+		if spd, err := strconv.Atoi(rest[4:7]); err == nil {
+			w.WindSpeed = float64(spd)
+		}
+		rest = rest[7:]
+	}
+
+	for len(rest) > 0 {
+		switch tag := rest[0]; tag {
+		case 'c', 'C', 's', 'S', 'g', 't', 'T':
+			if len(rest) < 4 {
+				rest = ""
+				continue
+			}
+			if v, err := strconv.Atoi(rest[1:4]); err == nil {
+				switch tag {
+				case 'c', 'C':
+					w.WindDirection = uint8(v)
+				case 's', 'S':
+					w.WindSpeed = float64(v)
+				case 'g':
+					w.WindGust = float64(v)
+				case 't', 'T':
+					w.Temperature = float64(v)
+				}
+			}
+			rest = rest[4:]
+		case 'r', 'R', 'p', 'P':
+			// Rain accumulation (last hour/24h/since midnight). Not tracked
+			// on WeatherReport, but skipped rather than aborting the scan
+			// so later fields (h, b, ...) still parse.
+			if len(rest) < 4 {
+				rest = ""
+				continue
+			}
+			rest = rest[4:]
+		case 'h':
+			if len(rest) < 3 {
+				rest = ""
+				continue
+			}
+			if v, err := strconv.Atoi(rest[1:3]); err == nil {
+				w.Humidity = uint8(v)
+			}
+			rest = rest[3:]
+		case 'b':
+			if len(rest) < 6 {
+				rest = ""
+				continue
+			}
+			if v, err := strconv.Atoi(rest[1:6]); err == nil {
+				w.Pressure = float64(v) / 10
+			}
+			rest = rest[6:]
+		default:
+			packet.Comment = rest
+			rest = ""
+		}
+	}
+	packet.Weather = w
+	return nil
+}
+
+var micEMessageNames = [8]string{
+	"Emergency", "Priority", "Special", "Committed",
+	"Returning", "In Service", "En Route", "Off Duty",
+}
+
+func micEMessageName(bits [3]byte) string {
+	idx := int(bits[0])<<2 | int(bits[1])<<1 | int(bits[2])
+	return micEMessageNames[idx]
+}
+
+// parseMicE decodes a Mic-E information field, whose latitude and message
+// bits are carried in the AX.25 destination callsign rather than in body
+// itself. Custom message types and position ambiguity are not supported,
+// matching the encoder's scope.
+func parseMicE(body string, packet *Packet) error {
+	if len(body) < 8 {
+		return errors.New("hamaprs: malformed mic-e body")
+	}
+	lat, bits, west, err := decodeMicEDestCallsign(packet.DestinationCallsign)
+	if err != nil {
+		return err
+	}
+	lon, err := decodeMicELongitude(body[1:4])
+	if err != nil {
+		return err
+	}
+	if west {
+		lon = -lon
+	}
+
+	packet.Latitude = lat
+	packet.Longitude = lon
+	if cs := body[4:6]; cs != "  " {
+		packet.Course, packet.Speed = decodeCompressedCourseSpeed(cs)
+	}
+	packet.Symbol = string([]byte{body[7], body[6]})
+	packet.Comment = body[8:]
+	packet.MicE = micEMessageName(bits)
+	return nil
+}
+
+func decodeMicEDestCallsign(dest string) (lat float64, bits [3]byte, west bool, err error) {
+	if len(dest) < 6 {
+		return 0, bits, false, errors.New("hamaprs: mic-e destination callsign too short")
+	}
+	var digits [6]int
+	for i := 0; i < 3; i++ {
+		d, bit, err := micEDigitValue(dest[i])
+		if err != nil {
+			return 0, bits, false, err
+		}
+		digits[i] = d
+		bits[i] = bit
+	}
+	var north bool
+	north, digits[3] = micEFlagDigitValue(dest[3])
+	_, digits[4] = micEFlagDigitValue(dest[4])
+	west, digits[5] = micEFlagDigitValue(dest[5])
+
+	deg := digits[0]*10 + digits[1]
+	minutes := float64(digits[2]*10+digits[3]) + float64(digits[4]*10+digits[5])/100
+	lat = float64(deg) + minutes/60
+	if !north {
+		lat = -lat
+	}
+	return lat, bits, west, nil
+}
+
+func micEDigitValue(c byte) (digit int, bit byte, err error) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), 0, nil
+	case c >= 'P' && c <= 'Y':
+		return int(c - 'P'), 1, nil
+	case c >= 'A' && c <= 'K':
+		return int(c - 'A'), 1, nil
+	default:
+		return 0, 0, fmt.Errorf("hamaprs: invalid mic-e digit %q", c)
+	}
+}
+
+func micEFlagDigitValue(c byte) (flag bool, digit int) {
+	if c >= 'A' && c <= 'J' {
+		return true, int(c - 'A')
+	}
+	if c >= '0' && c <= '9' {
+		return false, int(c - '0')
+	}
+	return false, 0 // 'K'/'L'/space: ambiguity markers, not supported
+}
+
+func decodeMicELongitude(s string) (float64, error) {
+	if len(s) != 3 {
+		return 0, errors.New("hamaprs: malformed mic-e longitude")
+	}
+	offset100 := s[0]&0x80 != 0
+	deg := int(s[0]&0x7F) - 28
+	if offset100 {
+		deg += 100
+	}
+	minByte := int(s[1]) - 28
+	hunByte := int(s[2]) - 28
+	minutes := float64(minByte) + float64(hunByte)/100
+	return float64(deg) + minutes/60, nil
+}
+
+func decodeCompressedCourseSpeed(s string) (uint8, float64) {
+	c := int(s[0]) - 33
+	sp := int(s[1]) - 33
+	knots := math.Pow(1.08, float64(sp)) - 1
+	return uint8(c * 4), knots * 1.852
+}
+
+func base91Decode(s string) int64 {
+	var v int64
+	for i := 0; i < len(s); i++ {
+		v = v*91 + int64(s[i]-33)
+	}
+	return v
+}
@@ -0,0 +1,10 @@
+//go:build !(cgo && fap)
+
+package hamaprs
+
+// NewParser returns the default Parser for this build configuration, which
+// here is the pure-Go nativeParser. Build with cgo and the "fap" tag to get
+// the libfap-backed Parser instead.
+func NewParser() Parser {
+	return NewNativeParser()
+}
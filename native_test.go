@@ -0,0 +1,177 @@
+package hamaprs
+
+import (
+	"math"
+	"testing"
+)
+
+// approxEqual reports whether a and b are within tol of each other, to
+// account for the precision lost in compressed/Mic-E encodings.
+func approxEqual(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol
+}
+
+func roundTrip(t *testing.T, p *Packet, opts EncodeOptions) *Packet {
+	t.Helper()
+	raw, err := EncodePacket(p, opts)
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+	parser := NewNativeParser()
+	got, err := parser.ParsePacket(raw, false)
+	if err != nil {
+		t.Fatalf("ParsePacket(%q): %v", raw, err)
+	}
+	return got
+}
+
+func TestLocationRoundTrip(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		p := BuildLocation("N0CALL", 37.775, -122.419, "/>")
+		p.Course = 88
+		p.Speed = 42.5 // km/h
+
+		got := roundTrip(t, p, EncodeOptions{Compressed: compressed})
+		if !approxEqual(got.Latitude, p.Latitude, 0.01) {
+			t.Errorf("compressed=%v: Latitude = %v, want ~%v", compressed, got.Latitude, p.Latitude)
+		}
+		if !approxEqual(got.Longitude, p.Longitude, 0.01) {
+			t.Errorf("compressed=%v: Longitude = %v, want ~%v", compressed, got.Longitude, p.Longitude)
+		}
+		if !approxEqual(got.Speed, p.Speed, 2) {
+			t.Errorf("compressed=%v: Speed = %v km/h, want ~%v km/h", compressed, got.Speed, p.Speed)
+		}
+	}
+}
+
+func TestLocationRoundTripSpeedParity(t *testing.T) {
+	// The same Packet.Speed must survive both encodings to within their
+	// respective rounding error, regardless of which one is used.
+	base := BuildLocation("N0CALL", 10, 10, "/>")
+	base.Course = 120
+	base.Speed = 65 // km/h
+
+	uncompressed := roundTrip(t, base, EncodeOptions{Compressed: false})
+	compressed := roundTrip(t, base, EncodeOptions{Compressed: true})
+
+	if !approxEqual(uncompressed.Speed, compressed.Speed, 3) {
+		t.Errorf("uncompressed and compressed round trips disagree on speed: %v km/h vs %v km/h",
+			uncompressed.Speed, compressed.Speed)
+	}
+}
+
+func TestMicERoundTrip(t *testing.T) {
+	p := BuildMicE("N0CALL", "En Route", 37.775, -122.419, "/>")
+
+	got := roundTrip(t, p, EncodeOptions{})
+	if got.PacketType != MicePacketType {
+		t.Fatalf("PacketType = %v, want MicePacketType", got.PacketType)
+	}
+	if !approxEqual(got.Latitude, p.Latitude, 0.01) {
+		t.Errorf("Latitude = %v, want ~%v", got.Latitude, p.Latitude)
+	}
+	if !approxEqual(got.Longitude, p.Longitude, 0.01) {
+		t.Errorf("Longitude = %v, want ~%v", got.Longitude, p.Longitude)
+	}
+	if got.MicE != p.MicE {
+		t.Errorf("MicE = %q, want %q", got.MicE, p.MicE)
+	}
+}
+
+func TestCombinedPositionWeather(t *testing.T) {
+	parser := NewNativeParser()
+	got, err := parser.ParsePacket("N0CALL>APRS:@092345z3745.00N/12225.00W_090/005g010t072r000p000P000h50b10150", false)
+	if err != nil {
+		t.Fatalf("ParsePacket: %v", err)
+	}
+	if got.PacketType != WXPacketType {
+		t.Fatalf("PacketType = %v, want WXPacketType", got.PacketType)
+	}
+	if !got.IncludePosition() {
+		t.Fatal("expected a position to be parsed")
+	}
+	if got.Weather == nil {
+		t.Fatal("Weather is nil")
+	}
+	if got.Weather.WindDirection != 90 || got.Weather.WindSpeed != 5 {
+		t.Errorf("wind = %v/%v, want 90/5", got.Weather.WindDirection, got.Weather.WindSpeed)
+	}
+	if got.Weather.Temperature != 72 || got.Weather.Humidity != 50 || got.Weather.Pressure != 1015 {
+		t.Errorf("Weather = %+v, want Temperature=72 Humidity=50 Pressure=1015", got.Weather)
+	}
+}
+
+func TestPositionlessWeather(t *testing.T) {
+	parser := NewNativeParser()
+	got, err := parser.ParsePacket("N0CALL>APRS:_10090556c220s004g005t077r000p000P000h50b09900", false)
+	if err != nil {
+		t.Fatalf("ParsePacket: %v", err)
+	}
+	if got.PacketType != WXPacketType {
+		t.Fatalf("PacketType = %v, want WXPacketType", got.PacketType)
+	}
+	if got.Weather == nil {
+		t.Fatal("Weather is nil")
+	}
+	if got.Weather.WindDirection != 220 || got.Weather.WindSpeed != 4 {
+		t.Errorf("wind = %v/%v, want 220/4", got.Weather.WindDirection, got.Weather.WindSpeed)
+	}
+	if got.Weather.Temperature != 77 || got.Weather.Humidity != 50 || got.Weather.Pressure != 990 {
+		t.Errorf("Weather = %+v, want Temperature=77 Humidity=50 Pressure=990", got.Weather)
+	}
+}
+
+func TestWeatherRoundTrip(t *testing.T) {
+	p := BuildWeather("N0CALL", WeatherReport{
+		WindDirection: 220,
+		WindSpeed:     4,
+		WindGust:      5,
+		Temperature:   77,
+		Humidity:      50,
+		Pressure:      990,
+	})
+	p.Timestamp = 1234567890
+
+	got := roundTrip(t, p, EncodeOptions{})
+	if got.PacketType != WXPacketType {
+		t.Fatalf("PacketType = %v, want WXPacketType", got.PacketType)
+	}
+	if got.Weather == nil {
+		t.Fatal("Weather is nil")
+	}
+	if *got.Weather != *p.Weather {
+		t.Errorf("Weather = %+v, want %+v", got.Weather, p.Weather)
+	}
+}
+
+func TestParseNMEA(t *testing.T) {
+	parser := NewNativeParser()
+
+	gga, err := parser.ParsePacket("N0CALL>APRS:$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47", false)
+	if err != nil {
+		t.Fatalf("ParsePacket GPGGA: %v", err)
+	}
+	if !approxEqual(gga.Latitude, 48+7.038/60, 1e-6) {
+		t.Errorf("GPGGA Latitude = %v, want %v", gga.Latitude, 48+7.038/60)
+	}
+	if !approxEqual(gga.Longitude, 11+31.0/60, 1e-6) {
+		t.Errorf("GPGGA Longitude = %v, want %v", gga.Longitude, 11+31.0/60)
+	}
+	if !approxEqual(gga.Altitude, 545.4, 1e-6) {
+		t.Errorf("GPGGA Altitude = %v, want 545.4", gga.Altitude)
+	}
+
+	rmc, err := parser.ParsePacket("N0CALL>APRS:$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A", false)
+	if err != nil {
+		t.Fatalf("ParsePacket GPRMC: %v", err)
+	}
+	if !approxEqual(rmc.Latitude, 48+7.038/60, 1e-6) {
+		t.Errorf("GPRMC Latitude = %v, want %v", rmc.Latitude, 48+7.038/60)
+	}
+	if !approxEqual(rmc.Speed, 22.4*1.852, 1e-6) {
+		t.Errorf("GPRMC Speed = %v km/h, want %v km/h", rmc.Speed, 22.4*1.852)
+	}
+	if rmc.Course != 84 {
+		t.Errorf("GPRMC Course = %v, want 84", rmc.Course)
+	}
+}
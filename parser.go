@@ -0,0 +1,10 @@
+package hamaprs
+
+// Parser turns raw APRS packet text into a Packet.
+type Parser interface {
+	// ParsePacket parses raw packet text into a new Packet.
+	ParsePacket(raw string, isAX25 bool) (*Packet, error)
+	// FillAprsPacket parses raw packet text into packet, reusing it instead
+	// of allocating a new one.
+	FillAprsPacket(raw string, isAX25 bool, packet *Packet) (*Packet, error)
+}
@@ -0,0 +1,170 @@
+package hamaprs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseTelemetry extracts a Telemetry reading from the "T#seq,a1,a2,a3,a4,a5,bbbbbbbb"
+// body found in a telemetry packet's raw text. ok is false if raw does not
+// contain a well-formed report. This is independent of the parser backend,
+// since neither libfap nor the APRS grammar proper break telemetry reports
+// down into structured fields.
+func parseTelemetry(raw string) (t Telemetry, ok bool) {
+	idx := strings.Index(raw, "T#")
+	if idx == -1 {
+		return Telemetry{}, false
+	}
+	fields := strings.Split(raw[idx+2:], ",")
+	if len(fields) < 7 {
+		return Telemetry{}, false
+	}
+
+	seq, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return Telemetry{}, false
+	}
+
+	var vals [5]float64
+	for i := range vals {
+		v, err := strconv.ParseFloat(strings.TrimSpace(fields[i+1]), 64)
+		if err != nil {
+			return Telemetry{}, false
+		}
+		vals[i] = v
+	}
+
+	// The digital field may be followed by free-text comment; only the
+	// leading run of '0'/'1' characters is the bit field.
+	digital := strings.TrimSpace(fields[6])
+	end := 0
+	for end < len(digital) && end < 8 && (digital[end] == '0' || digital[end] == '1') {
+		end++
+	}
+	var bits uint8
+	for i := 0; i < end; i++ {
+		if digital[i] == '1' {
+			bits |= 1 << uint(7-i)
+		}
+	}
+
+	return Telemetry{
+		Seq: seq, Val1: vals[0], Val2: vals[1], Val3: vals[2], Val4: vals[3], Val5: vals[4],
+		Digital: bits,
+	}, true
+}
+
+// TelemetryDefinition accumulates the PARM./UNIT./EQNS./BITS. messages a
+// station sends to describe how to interpret its own telemetry reports
+// (APRS 1.0.1 section 13). The zero value is usable: Apply passes raw
+// values through as-is until Update has learned an equation for a channel,
+// and leaves names and units empty until it has learned labels.
+type TelemetryDefinition struct {
+	Names    [5]string
+	Units    [5]string
+	Coeffs   [5][3]float64 // a, b, c of a*v^2 + b*v + c, per analog channel
+	BitNames [8]string
+	BitsOn   [8]bool
+	Title    string
+}
+
+// Update applies one telemetry metadata message - the Message of a packet
+// whose PacketType is TelemetryMessagePacketType - to td. It reports whether
+// msg was a recognised PARM./UNIT./EQNS./BITS. message.
+func (td *TelemetryDefinition) Update(msg string) bool {
+	switch {
+	case strings.HasPrefix(msg, "PARM."):
+		fields := strings.Split(msg[len("PARM."):], ",")
+		for i := 0; i < 5 && i < len(fields); i++ {
+			td.Names[i] = fields[i]
+		}
+		for i := 0; i < 8 && i+5 < len(fields); i++ {
+			td.BitNames[i] = fields[i+5]
+		}
+		return true
+
+	case strings.HasPrefix(msg, "UNIT."):
+		fields := strings.Split(msg[len("UNIT."):], ",")
+		for i := 0; i < 5 && i < len(fields); i++ {
+			td.Units[i] = fields[i]
+		}
+		return true
+
+	case strings.HasPrefix(msg, "EQNS."):
+		fields := strings.Split(msg[len("EQNS."):], ",")
+		for ch := 0; ch < 5; ch++ {
+			for coeff := 0; coeff < 3; coeff++ {
+				idx := ch*3 + coeff
+				if idx >= len(fields) {
+					break
+				}
+				v, err := strconv.ParseFloat(strings.TrimSpace(fields[idx]), 64)
+				if err == nil {
+					td.Coeffs[ch][coeff] = v
+				}
+			}
+		}
+		return true
+
+	case strings.HasPrefix(msg, "BITS."):
+		rest := msg[len("BITS."):]
+		mask, title, _ := strings.Cut(rest, ",")
+		for i := 0; i < 8 && i < len(mask); i++ {
+			td.BitsOn[i] = mask[i] == '1'
+		}
+		td.Title = title
+		return true
+	}
+	return false
+}
+
+// AnalogValue is one telemetry analog channel converted to engineering
+// units.
+type AnalogValue struct {
+	Name  string
+	Unit  string
+	Value float64
+}
+
+// DigitalValue is one telemetry digital bit, labelled from the definition.
+// On is the raw bit as received; the definition's BitsOn mask is not
+// applied to it.
+type DigitalValue struct {
+	Name string
+	On   bool
+}
+
+// TelemetryValues holds one telemetry reading converted to engineering
+// units via a TelemetryDefinition.
+type TelemetryValues struct {
+	Seq     int
+	Analog  [5]AnalogValue
+	Digital [8]DigitalValue
+}
+
+// Apply converts a raw Telemetry reading to engineering units by running
+// the quadratic a*v^2 + b*v + c per analog channel (APRS 1.0.1 section
+// 13.3), labelling each channel and bit from td. A channel with no known
+// equation (all-zero coefficients) is passed through unchanged.
+func (td *TelemetryDefinition) Apply(t Telemetry) TelemetryValues {
+	raw := [5]float64{t.Val1, t.Val2, t.Val3, t.Val4, t.Val5}
+	values := TelemetryValues{Seq: t.Seq}
+	for i := range raw {
+		a, b, c := td.Coeffs[i][0], td.Coeffs[i][1], td.Coeffs[i][2]
+		if a == 0 && b == 0 && c == 0 {
+			b = 1
+		}
+		values.Analog[i] = AnalogValue{
+			Name:  td.Names[i],
+			Unit:  td.Units[i],
+			Value: a*raw[i]*raw[i] + b*raw[i] + c,
+		}
+	}
+	for i := range values.Digital {
+		values.Digital[i] = DigitalValue{
+			Name: td.BitNames[i],
+			On:   t.Digital&(1<<uint(7-i)) != 0,
+		}
+	}
+	return values
+}
@@ -0,0 +1,119 @@
+package hamaprs
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/literadix/hamaprs/ax25"
+	"github.com/literadix/hamaprs/kiss"
+)
+
+// TNCReader reads and writes APRS packets over a KISS-framed TNC connection
+// (Direwolf, TNC-Pi, a serial KH-1, ...), feeding the reassembled TNC2 form
+// of each received AX.25 UI frame into FillAprsPacket.
+type TNCReader struct {
+	kr     *kiss.Reader
+	kw     *kiss.Writer
+	parser Parser
+}
+
+// NewTNCReader wraps rw, a KISS TNC connection, for receiving and
+// transmitting APRS packets.
+func NewTNCReader(rw io.ReadWriter) *TNCReader {
+	return &TNCReader{
+		kr:     kiss.NewReader(rw),
+		kw:     kiss.NewWriter(rw),
+		parser: NewParser(),
+	}
+}
+
+// ReadPacket blocks until a KISS frame carrying a decodable APRS UI frame
+// has been received, and returns it parsed. Non-data KISS frames (TNC
+// hardware/control commands) and non-APRS AX.25 traffic are skipped.
+func (t *TNCReader) ReadPacket() (*Packet, error) {
+	for {
+		frame, err := t.kr.ReadFrame()
+		if err != nil {
+			return nil, err
+		}
+		if frame.Command != kiss.CommandData {
+			continue
+		}
+		f, err := ax25.Decode(frame.Payload)
+		if err != nil {
+			continue
+		}
+		if f.PID != 0xF0 {
+			continue // not "no layer 3", i.e. not APRS
+		}
+		return t.parser.ParsePacket(tnc2Form(f), true)
+	}
+}
+
+// WritePacket encodes p, wraps it as an AX.25 UI frame and writes it to the
+// TNC as a KISS data frame on port 0.
+func (t *TNCReader) WritePacket(p *Packet) error {
+	raw, err := p.Encode()
+	if err != nil {
+		return err
+	}
+	f, err := tnc2ToAX25(raw)
+	if err != nil {
+		return err
+	}
+	return t.kw.WriteFrame(0, kiss.CommandData, ax25.Encode(f))
+}
+
+// tnc2Form renders a decoded AX.25 UI frame as the "SRC>DST,PATH:INFO" TNC2
+// text FillAprsPacket expects.
+func tnc2Form(f *ax25.Frame) string {
+	header := f.Source.String() + ">" + f.Destination.String()
+	if len(f.Path) > 0 {
+		parts := make([]string, len(f.Path))
+		for i, a := range f.Path {
+			parts[i] = a.String()
+		}
+		header += "," + strings.Join(parts, ",")
+	}
+	return header + ":" + string(f.Info)
+}
+
+// tnc2ToAX25 parses the "SRC>DST,PATH:INFO" TNC2 text produced by Encode
+// back into an AX.25 UI frame.
+func tnc2ToAX25(raw string) (*ax25.Frame, error) {
+	header, info, ok := strings.Cut(raw, ":")
+	if !ok {
+		return nil, fmt.Errorf("hamaprs: malformed TNC2 text %q", raw)
+	}
+	srcDst, pathStr, _ := strings.Cut(header, ",")
+	src, dst, ok := strings.Cut(srcDst, ">")
+	if !ok {
+		return nil, fmt.Errorf("hamaprs: malformed TNC2 header %q", header)
+	}
+
+	f := &ax25.Frame{
+		Destination: parseAddress(dst),
+		Source:      parseAddress(src),
+		PID:         0xF0,
+		Info:        []byte(info),
+	}
+	if pathStr != "" {
+		for _, p := range strings.Split(pathStr, ",") {
+			f.Path = append(f.Path, parseAddress(p))
+		}
+	}
+	return f, nil
+}
+
+func parseAddress(s string) ax25.Address {
+	repeated := strings.HasSuffix(s, "*")
+	s = strings.TrimSuffix(s, "*")
+	call, ssidStr, _ := strings.Cut(s, "-")
+	var ssid byte
+	if n, err := strconv.Atoi(ssidStr); err == nil {
+		ssid = byte(n)
+	}
+	return ax25.Address{Callsign: call, SSID: ssid, Repeated: repeated}
+}
@@ -0,0 +1,35 @@
+package hamaprs
+
+import (
+	"testing"
+
+	"github.com/literadix/hamaprs/ax25"
+)
+
+func TestTNC2AX25RoundTrip(t *testing.T) {
+	raw := "N0CALL-9>APRS,WIDE1-1,WIDE2-2*:!3745.00N/12225.00W>088/042Comment"
+
+	f, err := tnc2ToAX25(raw)
+	if err != nil {
+		t.Fatalf("tnc2ToAX25: %v", err)
+	}
+	if got := tnc2Form(f); got != raw {
+		t.Errorf("tnc2Form(tnc2ToAX25(%q)) = %q, want %q", raw, got, raw)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want ax25.Address
+	}{
+		{"N0CALL", ax25.Address{Callsign: "N0CALL"}},
+		{"N0CALL-9", ax25.Address{Callsign: "N0CALL", SSID: 9}},
+		{"WIDE2-2*", ax25.Address{Callsign: "WIDE2", SSID: 2, Repeated: true}},
+	}
+	for _, c := range cases {
+		if got := parseAddress(c.raw); got != c.want {
+			t.Errorf("parseAddress(%q) = %+v, want %+v", c.raw, got, c.want)
+		}
+	}
+}